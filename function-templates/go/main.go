@@ -10,21 +10,58 @@ Input:
   - Credentials injected as environment variables (e.g., API_KEY)
 
 Output:
-  - Write JSON to stdout (the function-runner captures this)
-  - Use stderr for logs (not captured as output)
+  - Line-delimited JSON events are written to stdout, each tagged with a
+    "type" field: "progress", "log", "partial", and a final "result" event.
+    The function-runner streams these events back to the workflow engine so
+    the UI can show real-time progress on nodes.
+  - Use the package-level log (log.Debug/log.Info/log.Error) for stderr
+    logs; it writes NDJSON with execution_id/workflow_id/node_id attached
+    and automatically redacts values of env vars that look like secrets
+    (SECRET_*, *_KEY, *_TOKEN) plus any JSON pointers listed in REDACT_KEYS.
 
 Example:
 
 	INPUT='{"name":"World"}' go run main.go
-	=> {"success":true,"result":"Hello, World!"}
+	=> {"type":"progress","percent":100,"message":"greeting 1/1"}
+	   {"type":"result","success":true,"result":"Hello, World!"}
+
+Run with --print-schema to print the input/output JSON Schema (draft-07)
+without executing the function, so the workflow-builder UI can introspect
+the container image and auto-generate a node config form.
+
+Retries:
+  - MAX_ATTEMPTS, ATTEMPT, RETRY_BACKOFF_MS, and TIMEOUT_MS are read from
+    the environment; Execute receives a context.Context that is cancelled
+    after TIMEOUT_MS. Use IsRetry() to detect a re-execution and
+    PreviousAttemptResult() to resume from the last attempt's Output
+    instead of restarting, keyed by IDEMPOTENCY_KEY.
+
+Tracing:
+  - TRACEPARENT and TRACESTATE (W3C Trace Context) are read from the
+    environment and used to start this execution's span as a child of the
+    parent workflow trace. Use Tracer() from inside Execute to create
+    child spans around external calls; spans are flushed via OTLP to
+    OTEL_EXPORTER_OTLP_ENDPOINT on exit.
 */
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ============================================================================
@@ -42,6 +79,45 @@ type Output struct {
 	Success bool   `json:"success"`
 	Result  string `json:"result,omitempty"`
 	Error   string `json:"error,omitempty"`
+
+	// ErrorType and Errors are populated by the runner harness, not by
+	// Execute, when INPUT or the returned Output fails schema validation.
+	ErrorType string        `json:"error_type,omitempty"`
+	Errors    []SchemaError `json:"errors,omitempty"`
+
+	// Retryable and ErrorClass tell the function-runner whether it is
+	// safe to reschedule this execution. Set them on failure; the
+	// harness also fills them in itself when TIMEOUT_MS is exceeded.
+	Retryable  bool   `json:"retryable,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+// InputSchema is the JSON Schema (draft-07) for Input. The runner harness
+// validates INPUT against this before calling Execute.
+func InputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"name":  map[string]interface{}{"type": "string"},
+			"count": map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+	}
+}
+
+// OutputSchema is the JSON Schema (draft-07) for Output. The runner harness
+// validates the value Execute returns against this before writing it.
+func OutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"success": map[string]interface{}{"type": "boolean"},
+			"result":  map[string]interface{}{"type": "string"},
+			"error":   map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"success"},
+	}
 }
 
 // SetDefaults sets default values for optional fields
@@ -58,11 +134,27 @@ func (i *Input) SetDefaults() {
 // MAIN FUNCTION LOGIC
 // ============================================================================
 
-// Execute contains your main function logic
-func Execute(input Input) Output {
+// Execute contains your main function logic. ctx is cancelled when
+// TIMEOUT_MS elapses; long-running functions should select on ctx.Done().
+func Execute(ctx context.Context, input Input) Output {
+	// Resume from a previous attempt instead of redoing the work, but only
+	// if it actually succeeded — a persisted failure must still be retried.
+	if IsRetry() {
+		if prev := PreviousAttemptResult(); prev != nil && prev.Success {
+			log.Info("resuming from previous attempt", map[string]interface{}{"attempt": AttemptNumber()})
+			return *prev
+		}
+	}
+
 	// Your custom logic here
 	var messages []string
 	for i := 0; i < input.Count; i++ {
+		select {
+		case <-ctx.Done():
+			return Output{Success: false, Error: ctx.Err().Error(), Retryable: true, ErrorClass: "transient"}
+		default:
+		}
+		EmitProgress(100*(i+1)/input.Count, fmt.Sprintf("greeting %d/%d", i+1, input.Count))
 		messages = append(messages, fmt.Sprintf("Hello, %s!", input.Name))
 	}
 
@@ -76,7 +168,105 @@ func Execute(input Input) Output {
 // RUNNER (DO NOT MODIFY BELOW)
 // ============================================================================
 
+// event is the envelope every line written to stdout is wrapped in. The
+// function-runner reads stdout line by line and dispatches on Type.
+type event struct {
+	Type string `json:"type"`
+}
+
+// progressEvent reports incremental completion of a long-running function.
+type progressEvent struct {
+	event
+	Percent int    `json:"percent"`
+	Message string `json:"message,omitempty"`
+}
+
+// logEvent carries a log line emitted by the function while it runs.
+type logEvent struct {
+	event
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// partialEvent carries an incremental chunk of result data, for functions
+// that want to stream results before they have a final answer.
+type partialEvent struct {
+	event
+	Data interface{} `json:"data"`
+}
+
+// resultEvent is the terminal event of the stream: the function's output.
+type resultEvent struct {
+	event
+	Output
+}
+
+// EmitProgress reports percent-complete (0-100) and an optional status
+// message. Call this as often as useful from inside Execute.
+func EmitProgress(percent int, message string) {
+	emit(progressEvent{event: event{Type: "progress"}, Percent: percent, Message: message})
+}
+
+// EmitLog streams a log line back to the workflow engine, in addition to
+// whatever is written to stderr. level is a free-form string such as
+// "info", "warn", or "error".
+func EmitLog(level, message string) {
+	emit(logEvent{event: event{Type: "log"}, Level: level, Message: message})
+}
+
+// EmitPartial streams an incremental chunk of result data before Execute
+// has finished. data is marshaled as-is.
+func EmitPartial(data interface{}) {
+	emit(partialEvent{event: event{Type: "partial"}, Data: data})
+}
+
+// EmitFinal writes the terminal "result" event for this execution. main()
+// calls this once Execute returns; you should not need to call it yourself.
+func EmitFinal(output Output) {
+	emit(resultEvent{event: event{Type: "result"}, Output: output})
+}
+
+// emit marshals v to a single JSON line and writes it to stdout.
+func emit(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Printf(`{"type":"result","success":false,"error":"failed to marshal output: %v"}`+"\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// SchemaError describes one JSON Schema validation failure.
+type SchemaError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func schemaFailure(errs []SchemaError) Output {
+	return Output{
+		Success:   false,
+		ErrorType: "schema",
+		Errors:    errs,
+	}
+}
+
 func main() {
+	printSchema := flag.Bool("print-schema", false, "print the input/output JSON Schema and exit")
+	flag.Parse()
+
+	if *printSchema {
+		data, err := json.Marshal(map[string]interface{}{
+			"input":  InputSchema(),
+			"output": OutputSchema(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[Function] failed to marshal schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
 	// Get input from environment variable
 	inputJSON := os.Getenv("INPUT")
 	if inputJSON == "" {
@@ -84,9 +274,19 @@ func main() {
 	}
 
 	// Log context for debugging (goes to stderr, not captured as output)
-	fmt.Fprintf(os.Stderr, "[Function] Execution ID: %s\n", getEnvOrDefault("EXECUTION_ID", "unknown"))
-	fmt.Fprintf(os.Stderr, "[Function] Workflow ID: %s\n", getEnvOrDefault("WORKFLOW_ID", "unknown"))
-	fmt.Fprintf(os.Stderr, "[Function] Node ID: %s\n", getEnvOrDefault("NODE_ID", "unknown"))
+	log.Debug("execution started")
+
+	// Validate raw INPUT against the declared schema before decoding it
+	// into the typed Input struct.
+	var rawInput interface{}
+	if err := json.Unmarshal([]byte(inputJSON), &rawInput); err != nil {
+		EmitFinal(Output{Success: false, Error: fmt.Sprintf("failed to parse input: %v", err)})
+		os.Exit(1)
+	}
+	if errs := validateSchema(rawInput, InputSchema(), ""); len(errs) > 0 {
+		EmitFinal(schemaFailure(errs))
+		os.Exit(1)
+	}
 
 	// Parse input
 	var input Input
@@ -95,20 +295,61 @@ func main() {
 			Success: false,
 			Error:   fmt.Sprintf("failed to parse input: %v", err),
 		}
-		writeOutput(output)
+		EmitFinal(output)
 		os.Exit(1)
 	}
 
 	// Set defaults
 	input.SetDefaults()
 
-	fmt.Fprintf(os.Stderr, "[Function] Input: %+v\n", input)
+	log.Info("received input", input)
 
-	// Execute the function
-	output := Execute(input)
+	// Derive a context that is cancelled once TIMEOUT_MS elapses, and run
+	// Execute on it so it cannot hang the container past its deadline.
+	ctx := context.Background()
+	if timeout := durationFromMillisEnv("TIMEOUT_MS"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	// Write output to stdout
-	writeOutput(output)
+	// Join the parent workflow trace and start this node's span.
+	// os.Exit skips deferred calls, so every exit below ends the span and
+	// flushes it explicitly through endTracing.
+	ctx, span, shutdownTracing := setupTracing(ctx)
+	endTracing := func() {
+		span.End()
+		shutdownTracing(context.Background())
+	}
+
+	output := runExecute(ctx, input)
+
+	// Validate the returned Output against the declared schema before
+	// writing it back to the workflow engine.
+	rawOutput, err := toRawJSON(output)
+	if err != nil {
+		EmitFinal(Output{Success: false, Error: fmt.Sprintf("failed to marshal output: %v", err)})
+		endTracing()
+		os.Exit(1)
+	}
+	if errs := validateSchema(rawOutput, OutputSchema(), ""); len(errs) > 0 {
+		EmitFinal(schemaFailure(errs))
+		endTracing()
+		os.Exit(1)
+	}
+
+	// Persist this attempt's result so a future retry sharing the same
+	// IDEMPOTENCY_KEY can resume via PreviousAttemptResult instead of
+	// redoing the work.
+	if key := IdempotencyKey(); key != "" {
+		if err := saveAttemptResult(key, output); err != nil {
+			log.Error("failed to persist attempt result", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	// Write the terminal result event to stdout
+	EmitFinal(output)
+	endTracing()
 
 	// Exit with appropriate code
 	if output.Success {
@@ -118,13 +359,254 @@ func main() {
 	}
 }
 
-func writeOutput(output Output) {
-	data, err := json.Marshal(output)
+// toRawJSON round-trips v through JSON into a generic interface{} tree
+// (map[string]interface{}, []interface{}, etc.) suitable for validateSchema.
+func toRawJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
 	if err != nil {
-		fmt.Printf(`{"success":false,"error":"failed to marshal output: %v"}`, err)
+		return nil, err
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// validateSchema checks value against a (subset of) JSON Schema draft-07:
+// type, properties, required, minimum/maximum. It returns one SchemaError
+// per failure, with path as a JSON pointer into value.
+func validateSchema(value interface{}, schema map[string]interface{}, path string) []SchemaError {
+	var errs []SchemaError
+
+	if wantType, ok := schema["type"].(string); ok {
+		if !matchesType(value, wantType) {
+			errs = append(errs, SchemaError{Path: path, Message: fmt.Sprintf("must be of type %s", wantType)})
+			return errs
+		}
+	}
+
+	if obj, ok := value.(map[string]interface{}); ok {
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				key, _ := r.(string)
+				if _, present := obj[key]; !present {
+					errs = append(errs, SchemaError{Path: path + "/" + key, Message: "is required"})
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]interface{}); ok {
+			for key, propSchema := range props {
+				propValue, present := obj[key]
+				if !present {
+					continue
+				}
+				ps, ok := propSchema.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				errs = append(errs, validateSchema(propValue, ps, path+"/"+key)...)
+			}
+		}
+	}
+
+	if num, ok := toFloat64(value); ok {
+		if min, ok := toFloat64(schema["minimum"]); ok && num < min {
+			errs = append(errs, SchemaError{Path: path, Message: fmt.Sprintf("must be >= %v", schema["minimum"])})
+		}
+		if max, ok := toFloat64(schema["maximum"]); ok && num > max {
+			errs = append(errs, SchemaError{Path: path, Message: fmt.Sprintf("must be <= %v", schema["maximum"])})
+		}
+	}
+
+	return errs
+}
+
+func matchesType(value interface{}, wantType string) bool {
+	switch wantType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		n, ok := toFloat64(value)
+		return ok && n == float64(int64(n))
+	case "number":
+		_, ok := toFloat64(value)
+		return ok
+	default:
+		return true
+	}
+}
+
+// toFloat64 accepts both JSON-decoded numbers (always float64) and the
+// numeric literals schema authors write in Go, e.g. "minimum": 1.
+func toFloat64(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// logLine is one NDJSON entry written to stderr.
+type logLine struct {
+	Time        string      `json:"time"`
+	Level       string      `json:"level"`
+	Message     string      `json:"message"`
+	ExecutionID string      `json:"execution_id,omitempty"`
+	WorkflowID  string      `json:"workflow_id,omitempty"`
+	NodeID      string      `json:"node_id,omitempty"`
+	Fields      interface{} `json:"fields,omitempty"`
+}
+
+// logger writes structured, secret-redacted NDJSON log lines to stderr,
+// with execution_id/workflow_id/node_id auto-attached from the environment.
+type logger struct {
+	executionID string
+	workflowID  string
+	nodeID      string
+	redactor    *redactor
+}
+
+// newLogger builds a logger from the function's environment.
+func newLogger() *logger {
+	return &logger{
+		executionID: getEnvOrDefault("EXECUTION_ID", "unknown"),
+		workflowID:  getEnvOrDefault("WORKFLOW_ID", "unknown"),
+		nodeID:      getEnvOrDefault("NODE_ID", "unknown"),
+		redactor:    newRedactor(),
+	}
+}
+
+func (l *logger) Debug(message string, fields ...interface{}) { l.write("debug", message, fields) }
+func (l *logger) Info(message string, fields ...interface{})  { l.write("info", message, fields) }
+func (l *logger) Error(message string, fields ...interface{}) { l.write("error", message, fields) }
+
+func (l *logger) write(level, message string, fields []interface{}) {
+	var redactedFields interface{}
+	if len(fields) == 1 {
+		redactedFields = l.redactor.redact(fields[0])
+	} else if len(fields) > 1 {
+		redactedFields = l.redactor.redact(fields)
+	}
+	line := logLine{
+		Time:        time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       level,
+		Message:     l.redactor.redactString(message),
+		ExecutionID: l.executionID,
+		WorkflowID:  l.workflowID,
+		NodeID:      l.nodeID,
+		Fields:      redactedFields,
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, `{"level":"error","message":"failed to marshal log line: %v"}`+"\n", err)
 		return
 	}
-	fmt.Println(string(data))
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// log is the package-wide logger, used as log.Info(...)/log.Debug(...)/log.Error(...).
+var log = newLogger()
+
+// redactor scrubs sensitive values out of anything passed to the logger.
+// It redacts by value (any environment variable that looks like a secret,
+// wherever its value appears) and by path (JSON pointers named in the
+// REDACT_KEYS environment variable, comma-separated, e.g. "/apiKey,/token").
+type redactor struct {
+	secretValues map[string]struct{}
+	redactPaths  map[string]struct{}
+}
+
+const redactedPlaceholder = "***REDACTED***"
+
+func newRedactor() *redactor {
+	r := &redactor{
+		secretValues: map[string]struct{}{},
+		redactPaths:  map[string]struct{}{},
+	}
+	for _, entry := range strings.Split(os.Getenv("REDACT_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			r.redactPaths[entry] = struct{}{}
+		}
+	}
+	for _, kv := range os.Environ() {
+		key, value, found := strings.Cut(kv, "=")
+		if !found || value == "" {
+			continue
+		}
+		if looksLikeSecretEnvVar(key) {
+			r.secretValues[value] = struct{}{}
+		}
+	}
+	return r
+}
+
+func looksLikeSecretEnvVar(key string) bool {
+	return strings.HasPrefix(key, "SECRET_") ||
+		strings.HasSuffix(key, "_KEY") ||
+		strings.HasSuffix(key, "_TOKEN")
+}
+
+// redact round-trips v through JSON and scrubs it, so it works against
+// structs, maps, and plain strings alike without per-type redaction code.
+func (r *redactor) redact(v interface{}) interface{} {
+	raw, err := toRawJSON(v)
+	if err != nil {
+		return redactedPlaceholder
+	}
+	return r.redactValue(raw, "")
+}
+
+func (r *redactor) redactValue(value interface{}, path string) interface{} {
+	if _, redact := r.redactPaths[path]; redact {
+		return redactedPlaceholder
+	}
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = r.redactValue(val, path+"/"+key)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = r.redactValue(val, fmt.Sprintf("%s/%d", path, i))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redactString scrubs every occurrence of a known secret value out of s,
+// not just whole-string matches, since secrets are often interpolated into
+// a larger message (e.g. "calling upstream with key=sk-...").
+func (r *redactor) redactString(s string) string {
+	for secret := range r.secretValues {
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	return s
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -133,3 +615,165 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// runExecute calls Execute and also enforces ctx's deadline itself, since a
+// function that ignores ctx would otherwise hang the container forever.
+func runExecute(ctx context.Context, input Input) Output {
+	resultCh := make(chan Output, 1)
+	go func() {
+		resultCh <- Execute(ctx, input)
+	}()
+	select {
+	case output := <-resultCh:
+		return output
+	case <-ctx.Done():
+		return Output{
+			Success:    false,
+			Error:      ctx.Err().Error(),
+			Retryable:  true,
+			ErrorClass: "transient",
+		}
+	}
+}
+
+// AttemptNumber returns the current try number (1 for the first attempt),
+// read from the ATTEMPT environment variable.
+func AttemptNumber() int {
+	n, err := strconv.Atoi(os.Getenv("ATTEMPT"))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// MaxAttempts returns the configured retry ceiling, read from
+// MAX_ATTEMPTS. Functions do not need to enforce this themselves; the
+// function-runner stops rescheduling once it is reached.
+func MaxAttempts() int {
+	n, err := strconv.Atoi(os.Getenv("MAX_ATTEMPTS"))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// IsRetry reports whether this is a re-execution of a previous attempt.
+func IsRetry() bool {
+	return AttemptNumber() > 1
+}
+
+// RetryBackoff returns how long the function-runner waited (or will wait)
+// before this attempt, read from RETRY_BACKOFF_MS. It is informational:
+// the function-runner owns actual retry scheduling, not the template.
+func RetryBackoff() time.Duration {
+	return durationFromMillisEnv("RETRY_BACKOFF_MS")
+}
+
+// IdempotencyKey returns the key this execution's attempts share, or "" if
+// the function-runner did not assign one (in which case retries restart
+// from scratch rather than resuming).
+func IdempotencyKey() string {
+	return os.Getenv("IDEMPOTENCY_KEY")
+}
+
+// PreviousAttemptResult loads the Output saved by the last attempt that
+// shared this execution's IDEMPOTENCY_KEY, or nil if there isn't one. The
+// saved Output may itself be a failure (Success: false) — callers that
+// want to resume rather than restart must check Success before using it.
+func PreviousAttemptResult() *Output {
+	key := IdempotencyKey()
+	if key == "" {
+		return nil
+	}
+	data, err := os.ReadFile(stateFilePath(key))
+	if err != nil {
+		return nil
+	}
+	var output Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil
+	}
+	return &output
+}
+
+// saveAttemptResult persists output under the given idempotency key so a
+// later retry's PreviousAttemptResult can find it.
+func saveAttemptResult(key string, output Output) error {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(stateFilePath(key), data, 0o644)
+}
+
+func stateDir() string {
+	return getEnvOrDefault("STATE_DIR", "/var/run/function-state")
+}
+
+func stateFilePath(key string) string {
+	return filepath.Join(stateDir(), key+".json")
+}
+
+// durationFromMillisEnv reads an environment variable as milliseconds and
+// returns it as a Duration, or 0 if unset/invalid (meaning "no limit").
+func durationFromMillisEnv(key string) time.Duration {
+	ms, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// tracer is the tracer for this execution, set up by setupTracing.
+var tracer trace.Tracer
+
+// Tracer returns this execution's tracer, so Execute can start child
+// spans around external calls (e.g. `Tracer().Start(ctx, "http.call")`).
+func Tracer() trace.Tracer {
+	return tracer
+}
+
+// setupTracing extracts the W3C Trace Context (TRACEPARENT/TRACESTATE) the
+// function-runner injected, starts this node's span as a child of the
+// parent workflow trace, and configures an OTLP exporter when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set. The returned shutdown func flushes
+// and must be called before the process exits.
+func setupTracing(ctx context.Context) (context.Context, trace.Span, func(context.Context)) {
+	propagator := propagation.TraceContext{}
+	carrier := propagation.MapCarrier{}
+	if tp := os.Getenv("TRACEPARENT"); tp != "" {
+		carrier.Set("traceparent", tp)
+	}
+	if ts := os.Getenv("TRACESTATE"); ts != "" {
+		carrier.Set("tracestate", ts)
+	}
+	ctx = propagator.Extract(ctx, carrier)
+	otel.SetTextMapPropagator(propagator)
+
+	shutdown := func(context.Context) {}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+		if err != nil {
+			log.Error("failed to configure OTLP exporter", map[string]interface{}{"error": err.Error()})
+		} else {
+			provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+			otel.SetTracerProvider(provider)
+			shutdown = func(shutdownCtx context.Context) {
+				if err := provider.Shutdown(shutdownCtx); err != nil {
+					log.Error("failed to flush spans", map[string]interface{}{"error": err.Error()})
+				}
+			}
+		}
+	}
+
+	tracer = otel.Tracer("function-runner/custom-function")
+	ctx, span := tracer.Start(ctx, getEnvOrDefault("NODE_NAME", "custom-function"), trace.WithAttributes(
+		attribute.String("workflow.id", getEnvOrDefault("WORKFLOW_ID", "unknown")),
+		attribute.String("execution.id", getEnvOrDefault("EXECUTION_ID", "unknown")),
+		attribute.String("node.id", getEnvOrDefault("NODE_ID", "unknown")),
+	))
+	return ctx, span, shutdown
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateSchemaMinimum(t *testing.T) {
+	schema := InputSchema()
+
+	tests := []struct {
+		name     string
+		input    string
+		wantPath string
+	}{
+		{name: "count below minimum", input: `{"name":"World","count":0}`, wantPath: "/count"},
+		{name: "within bounds", input: `{"name":"World","count":1}`, wantPath: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw interface{}
+			if err := json.Unmarshal([]byte(tt.input), &raw); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			errs := validateSchema(raw, schema, "")
+			if tt.wantPath == "" {
+				if len(errs) != 0 {
+					t.Fatalf("expected no validation errors, got %+v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 {
+				t.Fatalf("expected a validation error, got none")
+			}
+			if errs[0].Path != tt.wantPath {
+				t.Fatalf("expected error path %q, got %q", tt.wantPath, errs[0].Path)
+			}
+		})
+	}
+}
+
+func TestRedactorRedactsWholeValueAndSubstring(t *testing.T) {
+	t.Setenv("API_KEY", "sk-supersecret-12345")
+	r := newRedactor()
+
+	if got := r.redactString("sk-supersecret-12345"); got != redactedPlaceholder {
+		t.Fatalf("whole-value redaction: got %q", got)
+	}
+	if want := "calling upstream with key=" + redactedPlaceholder; r.redactString("calling upstream with key=sk-supersecret-12345") != want {
+		t.Fatalf("substring redaction: got %q, want %q", r.redactString("calling upstream with key=sk-supersecret-12345"), want)
+	}
+}
+
+func TestExecuteResumesOnlyFromSuccessfulPreviousAttempt(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("STATE_DIR", stateDir)
+	t.Setenv("IDEMPOTENCY_KEY", "test-key")
+	t.Setenv("ATTEMPT", "2")
+
+	input := Input{Name: "World", Count: 1}
+
+	t.Run("does not resume from a failed attempt", func(t *testing.T) {
+		writeAttemptResult(t, stateDir, "test-key", Output{Success: false, Error: "boom", Retryable: true, ErrorClass: "transient"})
+		out := Execute(context.Background(), input)
+		if !out.Success {
+			t.Fatalf("expected Execute to redo the work instead of replaying the failure, got %+v", out)
+		}
+	})
+
+	t.Run("resumes from a successful attempt", func(t *testing.T) {
+		writeAttemptResult(t, stateDir, "test-key", Output{Success: true, Result: "cached"})
+		out := Execute(context.Background(), input)
+		if out.Result != "cached" {
+			t.Fatalf("expected Execute to replay the cached result, got %+v", out)
+		}
+	})
+}
+
+func writeAttemptResult(t *testing.T, dir, key string, output Output) {
+	t.Helper()
+	data, err := json.Marshal(output)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key+".json"), data, 0o644); err != nil {
+		t.Fatalf("write state file: %v", err)
+	}
+}